@@ -0,0 +1,68 @@
+package main
+
+import (
+    "github.com/hashicorp/hcl/v2"
+)
+
+// pos and hclRange mirror hcl.Pos/hcl.Range in JSON so Python can locate a
+// diagnostic precisely instead of re-parsing an error string.
+type pos struct {
+    Line   int `json:"line"`
+    Column int `json:"column"`
+    Byte   int `json:"byte"`
+}
+
+type hclRange struct {
+    Filename string `json:"filename"`
+    Start    pos    `json:"start"`
+    End      pos    `json:"end"`
+}
+
+type richDiagnostic struct {
+    Severity string    `json:"severity"`
+    Summary  string    `json:"summary"`
+    Detail   string    `json:"detail,omitempty"`
+    Subject  *hclRange `json:"subject,omitempty"`
+    Context  *hclRange `json:"context,omitempty"`
+}
+
+func toHCLRange(r *hcl.Range) *hclRange {
+    if r == nil {
+        return nil
+    }
+    return &hclRange{
+        Filename: r.Filename,
+        Start:    pos{Line: r.Start.Line, Column: r.Start.Column, Byte: r.Start.Byte},
+        End:      pos{Line: r.End.Line, Column: r.End.Column, Byte: r.End.Byte},
+    }
+}
+
+func toRichDiagnostics(diags hcl.Diagnostics) []richDiagnostic {
+    out := make([]richDiagnostic, 0, len(diags))
+    for _, d := range diags {
+        out = append(out, richDiagnostic{
+            Severity: severityName(d.Severity),
+            Summary:  d.Summary,
+            Detail:   d.Detail,
+            Subject:  toHCLRange(d.Subject),
+            Context:  toHCLRange(d.Context),
+        })
+    }
+    return out
+}
+
+// diagnosticsForError recovers hcl.Diagnostics (with source positions) for a
+// failure from convertToObject. Both the native-HCL and JSON-syntax parse
+// paths there return hcl.Diagnostics directly as the error value when
+// parsing fails, so that's a plain type assertion and carries real source
+// positions. Failures after a successful parse (e.g. convert.File rejecting
+// a file that mixes labeled and unlabeled blocks of the same name) surface
+// as a plain error with no position info available from the underlying
+// converter, so those fall back to a single positionless diagnostic.
+func diagnosticsForError(b []byte, filename string, err error) []richDiagnostic {
+    if diags, ok := err.(hcl.Diagnostics); ok {
+        return toRichDiagnostics(diags)
+    }
+
+    return []richDiagnostic{{Severity: "error", Summary: err.Error()}}
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "github.com/hashicorp/hcl/v2"
+    "github.com/hashicorp/hcl/v2/hclparse"
+    "github.com/zclconf/go-cty/cty"
+)
+
+// inspectSchema lists the top-level blocks we care about for --inspect. We
+// walk against hcl/v2 directly (rather than pulling in
+// terraform-config-inspect/tfconfig) and use PartialContent so any block or
+// attribute we don't recognize is silently ignored instead of erroring.
+var inspectSchema = &hcl.BodySchema{
+    Blocks: []hcl.BlockHeaderSchema{
+        {Type: "terraform"},
+        {Type: "variable", LabelNames: []string{"name"}},
+        {Type: "output", LabelNames: []string{"name"}},
+        {Type: "resource", LabelNames: []string{"type", "name"}},
+        {Type: "data", LabelNames: []string{"type", "name"}},
+        {Type: "module", LabelNames: []string{"name"}},
+    },
+}
+
+type diagnostic struct {
+    Severity string `json:"severity"`
+    Summary  string `json:"summary"`
+    Detail   string `json:"detail"`
+    File     string `json:"file,omitempty"`
+    Line     int    `json:"line,omitempty"`
+}
+
+// inspectModule walks every *.tf and *.tf.json file directly inside dir and
+// builds the normalized module summary emitted by --inspect.
+func inspectModule(dir string) (map[string]any, error) {
+    entries, err := os.ReadDir(dir)
+    if err != nil {
+        return nil, fmt.Errorf("reading module directory: %w", err)
+    }
+
+    var names []string
+    for _, e := range entries {
+        if e.IsDir() {
+            continue
+        }
+        n := e.Name()
+        if strings.HasSuffix(n, ".tf") || strings.HasSuffix(n, ".tf.json") {
+            names = append(names, n)
+        }
+    }
+    sort.Strings(names)
+
+    variables := map[string]any{}
+    outputs := map[string]any{}
+    requiredCore := []string{}
+    requiredProviders := map[string]any{}
+    managedResources := map[string]any{}
+    dataResources := map[string]any{}
+    moduleCalls := map[string]any{}
+    var diags []diagnostic
+
+    parser := hclparse.NewParser()
+
+    for _, name := range names {
+        fp := filepath.Join(dir, name)
+        var (
+            file   *hcl.File
+            pdiags hcl.Diagnostics
+        )
+        if strings.HasSuffix(name, ".tf.json") {
+            file, pdiags = parser.ParseJSONFile(fp)
+        } else {
+            file, pdiags = parser.ParseHCLFile(fp)
+        }
+        diags = append(diags, diagsToInspect(pdiags)...)
+        if file == nil || file.Body == nil {
+            continue
+        }
+
+        content, _, cdiags := file.Body.PartialContent(inspectSchema)
+        diags = append(diags, diagsToInspect(cdiags)...)
+
+        for _, block := range content.Blocks {
+            switch block.Type {
+            case "terraform":
+                inspectTerraformBlock(block, &requiredCore, requiredProviders, &diags)
+            case "variable":
+                inspectVariableBlock(block, variables, &diags)
+            case "output":
+                inspectOutputBlock(block, outputs, &diags)
+            case "resource":
+                inspectResourceBlock(block, fp, managedResources, "", &diags)
+            case "data":
+                inspectResourceBlock(block, fp, dataResources, "data.", &diags)
+            case "module":
+                inspectModuleBlock(block, fp, moduleCalls, &diags)
+            }
+        }
+    }
+
+    return map[string]any{
+        "path":               dir,
+        "variables":          variables,
+        "outputs":            outputs,
+        "required_core":      requiredCore,
+        "required_providers": requiredProviders,
+        "managed_resources":  managedResources,
+        "data_resources":     dataResources,
+        "module_calls":       moduleCalls,
+        "diagnostics":        diags,
+    }, nil
+}
+
+func diagsToInspect(diags hcl.Diagnostics) []diagnostic {
+    out := make([]diagnostic, 0, len(diags))
+    for _, d := range diags {
+        di := diagnostic{
+            Severity: severityName(d.Severity),
+            Summary:  d.Summary,
+            Detail:   d.Detail,
+        }
+        if d.Subject != nil {
+            di.File = d.Subject.Filename
+            di.Line = d.Subject.Start.Line
+        }
+        out = append(out, di)
+    }
+    return out
+}
+
+func severityName(s hcl.DiagnosticSeverity) string {
+    switch s {
+    case hcl.DiagError:
+        return "error"
+    case hcl.DiagWarning:
+        return "warning"
+    default:
+        return "invalid"
+    }
+}
+
+func blockPos(filename string, r hcl.Range) map[string]any {
+    return map[string]any{
+        "filename": filename,
+        "line":     r.Start.Line,
+    }
+}
+
+func addDuplicateDiag(diags *[]diagnostic, kind, name string, r hcl.Range) {
+    *diags = append(*diags, diagnostic{
+        Severity: "warning",
+        Summary:  fmt.Sprintf("Duplicate %s %q", kind, name),
+        Detail:   fmt.Sprintf("%s %q is declared more than once; keeping the first declaration.", kind, name),
+        File:     r.Filename,
+        Line:     r.Start.Line,
+    })
+}
+
+func evalAttr(attrs hcl.Attributes, name string) (cty.Value, hcl.Expression, bool) {
+    attr, ok := attrs[name]
+    if !ok {
+        return cty.NilVal, nil, false
+    }
+    v, _ := attr.Expr.Value(nil)
+    return v, attr.Expr, true
+}
+
+func inspectVariableBlock(block *hcl.Block, variables map[string]any, diags *[]diagnostic) {
+    name := block.Labels[0]
+    if _, exists := variables[name]; exists {
+        addDuplicateDiag(diags, "variable", name, block.DefRange)
+        return
+    }
+
+    attrs, _ := block.Body.JustAttributes()
+
+    entry := map[string]any{
+        "type":        "",
+        "default":     nil,
+        "description": "",
+        "sensitive":   false,
+        "required":    true,
+    }
+
+    if _, expr, ok := evalAttr(attrs, "type"); ok {
+        if kw := hcl.ExprAsKeyword(expr); kw != "" {
+            entry["type"] = kw
+        } else {
+            entry["type"] = string(expr.Range().SliceBytes(sourceBytes(expr)))
+        }
+    }
+    if v, _, ok := evalAttr(attrs, "default"); ok && v != cty.NilVal && v.IsWhollyKnown() {
+        entry["default"] = ctyToNative(v)
+        entry["required"] = false
+    }
+    if v, _, ok := evalAttr(attrs, "description"); ok && v.Type() == cty.String {
+        entry["description"] = v.AsString()
+    }
+    if v, _, ok := evalAttr(attrs, "sensitive"); ok && v.Type() == cty.Bool {
+        entry["sensitive"] = v.True()
+    }
+
+    variables[name] = entry
+}
+
+func inspectOutputBlock(block *hcl.Block, outputs map[string]any, diags *[]diagnostic) {
+    name := block.Labels[0]
+    if _, exists := outputs[name]; exists {
+        addDuplicateDiag(diags, "output", name, block.DefRange)
+        return
+    }
+
+    attrs, _ := block.Body.JustAttributes()
+    entry := map[string]any{
+        "description": "",
+        "sensitive":   false,
+    }
+    if v, _, ok := evalAttr(attrs, "description"); ok && v.Type() == cty.String {
+        entry["description"] = v.AsString()
+    }
+    if v, _, ok := evalAttr(attrs, "sensitive"); ok && v.Type() == cty.Bool {
+        entry["sensitive"] = v.True()
+    }
+    outputs[name] = entry
+}
+
+func inspectResourceBlock(block *hcl.Block, filename string, into map[string]any, addressPrefix string, diags *[]diagnostic) {
+    resourceType, name := block.Labels[0], block.Labels[1]
+    address := addressPrefix + resourceType + "." + name
+    if _, exists := into[address]; exists {
+        addDuplicateDiag(diags, "resource", address, block.DefRange)
+        return
+    }
+
+    attrs, _ := block.Body.JustAttributes()
+    provider := providerFromType(resourceType)
+    if v, _, ok := evalAttr(attrs, "provider"); ok && v.Type() == cty.String {
+        provider = v.AsString()
+    }
+
+    into[address] = map[string]any{
+        "type":     resourceType,
+        "name":     name,
+        "provider": provider,
+        "pos":      blockPos(filename, block.DefRange),
+    }
+}
+
+func inspectModuleBlock(block *hcl.Block, filename string, moduleCalls map[string]any, diags *[]diagnostic) {
+    name := block.Labels[0]
+    if _, exists := moduleCalls[name]; exists {
+        addDuplicateDiag(diags, "module call", name, block.DefRange)
+        return
+    }
+
+    attrs, _ := block.Body.JustAttributes()
+    entry := map[string]any{
+        "source":    "",
+        "version":   "",
+        "providers": map[string]any{},
+        "pos":       blockPos(filename, block.DefRange),
+    }
+    if v, _, ok := evalAttr(attrs, "source"); ok && v.Type() == cty.String {
+        entry["source"] = v.AsString()
+    }
+    if v, _, ok := evalAttr(attrs, "version"); ok && v.Type() == cty.String {
+        entry["version"] = v.AsString()
+    }
+    if providersAttr, ok := attrs["providers"]; ok {
+        if pairs, pdiags := hcl.ExprMap(providersAttr.Expr); !pdiags.HasErrors() {
+            providers := map[string]any{}
+            for _, pair := range pairs {
+                k, _ := pair.Key.Value(nil)
+                if k.Type() != cty.String {
+                    continue
+                }
+                providers[k.AsString()] = exprSource(pair.Value)
+            }
+            entry["providers"] = providers
+        }
+    }
+    moduleCalls[name] = entry
+}
+
+func inspectTerraformBlock(block *hcl.Block, requiredCore *[]string, requiredProviders map[string]any, diags *[]diagnostic) {
+    attrs, _ := block.Body.JustAttributes()
+    if v, _, ok := evalAttr(attrs, "required_version"); ok && v.Type() == cty.String {
+        *requiredCore = append(*requiredCore, v.AsString())
+    }
+
+    inner, _, idiags := block.Body.PartialContent(&hcl.BodySchema{
+        Blocks: []hcl.BlockHeaderSchema{{Type: "required_providers"}},
+    })
+    *diags = append(*diags, diagsToInspect(idiags)...)
+
+    for _, rp := range inner.Blocks {
+        rpAttrs, _ := rp.Body.JustAttributes()
+        for provName, attr := range rpAttrs {
+            v, _ := attr.Expr.Value(nil)
+            entry := map[string]any{
+                "source":             "hashicorp/" + provName,
+                "version_constraints": []string{},
+            }
+            switch {
+            case v.Type() == cty.String:
+                entry["version_constraints"] = []string{v.AsString()}
+            case v.Type().IsObjectType():
+                if src, ok := v.AsValueMap()["source"]; ok && src.Type() == cty.String {
+                    entry["source"] = src.AsString()
+                }
+                if ver, ok := v.AsValueMap()["version"]; ok && ver.Type() == cty.String {
+                    entry["version_constraints"] = []string{ver.AsString()}
+                }
+            }
+            if _, exists := requiredProviders[provName]; exists {
+                *diags = append(*diags, diagnostic{
+                    Severity: "warning",
+                    Summary:  fmt.Sprintf("Duplicate required provider %q", provName),
+                    Detail:   fmt.Sprintf("provider %q is declared more than once across required_providers blocks.", provName),
+                    File:     attr.Range.Filename,
+                    Line:     attr.Range.Start.Line,
+                })
+                continue
+            }
+            requiredProviders[provName] = entry
+        }
+    }
+}
+
+func providerFromType(resourceType string) string {
+    if i := strings.Index(resourceType, "_"); i > 0 {
+        return resourceType[:i]
+    }
+    return resourceType
+}
+
+func exprSource(expr hcl.Expression) string {
+    if kw := hcl.ExprAsKeyword(expr); kw != "" {
+        return kw
+    }
+    v, err := expr.Value(nil)
+    if err == nil && v.Type() == cty.String {
+        return v.AsString()
+    }
+    return ""
+}
+
+func sourceBytes(expr hcl.Expression) []byte {
+    // Variable "type" expressions (string, list(string), ...) use bare
+    // keywords/calls that aren't valid cty values, so we fall back to the
+    // expression's own source range rather than evaluating it.
+    rng := expr.Range()
+    b, err := os.ReadFile(rng.Filename)
+    if err != nil {
+        return nil
+    }
+    return b
+}
+
+// ctyToNative converts a (wholly known) cty.Value into plain Go data so it
+// round-trips through encoding/json the same way convert.Bytes output does.
+func ctyToNative(v cty.Value) any {
+    if v.IsNull() {
+        return nil
+    }
+    switch {
+    case v.Type() == cty.String:
+        return v.AsString()
+    case v.Type() == cty.Bool:
+        return v.True()
+    case v.Type() == cty.Number:
+        f, _ := v.AsBigFloat().Float64()
+        return f
+    case v.Type().IsListType(), v.Type().IsTupleType(), v.Type().IsSetType():
+        out := make([]any, 0)
+        for it := v.ElementIterator(); it.Next(); {
+            _, ev := it.Element()
+            out = append(out, ctyToNative(ev))
+        }
+        return out
+    case v.Type().IsMapType(), v.Type().IsObjectType():
+        out := map[string]any{}
+        for it := v.ElementIterator(); it.Next(); {
+            k, ev := it.Element()
+            out[k.AsString()] = ctyToNative(ev)
+        }
+        return out
+    default:
+        return nil
+    }
+}
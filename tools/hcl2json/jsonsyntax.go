@@ -0,0 +1,91 @@
+package main
+
+// jsonBlockSchema describes, for Terraform's JSON surface syntax, which keys
+// at which nesting depth are blocks rather than attributes, and how many
+// label levels each block type consumes before reaching its body. It mirrors
+// convert.Bytes' treatment of the same block types in native HCL syntax, so
+// that wrapJSONBlocks can make hand-written .tf.json/.tfvars.json input match
+// convert.Bytes' canonical shape byte-for-byte.
+type jsonBlockSchema struct {
+    labelCount int
+    nested     map[string]*jsonBlockSchema
+}
+
+var terraformJSONBlocks = map[string]*jsonBlockSchema{
+    "terraform": {labelCount: 0, nested: map[string]*jsonBlockSchema{
+        "required_providers": {labelCount: 0},
+        "backend":            {labelCount: 1},
+        "cloud":              {labelCount: 0},
+    }},
+    "variable": {labelCount: 1},
+    "output":   {labelCount: 1},
+    "provider": {labelCount: 1},
+    "resource": {labelCount: 2},
+    "data":     {labelCount: 2},
+    "module":   {labelCount: 1},
+    "locals":   {labelCount: 0},
+}
+
+// wrapJSONBlocks rewrites obj in place so that every known Terraform block
+// type is array-wrapped at its final label level, matching convert.Bytes'
+// "always wrap the value in a collection" behavior for native HCL blocks
+// (see convertBlock in github.com/tmccombs/hcl2json/convert). Keys that
+// aren't recognized block types, and attribute values at any depth
+// (including object literals), are left untouched.
+func wrapJSONBlocks(obj map[string]any) {
+    wrapNestedJSONBlocks(obj, terraformJSONBlocks)
+}
+
+func wrapNestedJSONBlocks(body map[string]any, schema map[string]*jsonBlockSchema) {
+    for key, s := range schema {
+        if v, exists := body[key]; exists {
+            body[key] = wrapJSONBlockValue(v, s)
+        }
+    }
+}
+
+// wrapJSONBlockValue descends labelCount levels of plain label maps, then
+// wraps the resulting body (or bodies, if the author already supplied an
+// array of repeated blocks) in a single-element JSON array per instance.
+func wrapJSONBlockValue(v any, s *jsonBlockSchema) any {
+    if s.labelCount > 0 {
+        labels, ok := v.(map[string]any)
+        if !ok {
+            return v
+        }
+        child := &jsonBlockSchema{labelCount: s.labelCount - 1, nested: s.nested}
+        out := make(map[string]any, len(labels))
+        for label, lv := range labels {
+            out[label] = wrapJSONBlockValue(lv, child)
+        }
+        return out
+    }
+
+    bodies := jsonBlockBodies(v)
+    wrapped := make([]any, 0, len(bodies))
+    for _, b := range bodies {
+        wrapNestedJSONBlocks(b, s.nested)
+        wrapped = append(wrapped, b)
+    }
+    return wrapped
+}
+
+// jsonBlockBodies normalizes a block's JSON value to a slice of bodies: a
+// lone object is one instance, while an array is however many instances the
+// author already wrote out explicitly.
+func jsonBlockBodies(v any) []map[string]any {
+    switch t := v.(type) {
+    case map[string]any:
+        return []map[string]any{t}
+    case []any:
+        out := make([]map[string]any, 0, len(t))
+        for _, e := range t {
+            if m, ok := e.(map[string]any); ok {
+                out = append(out, m)
+            }
+        }
+        return out
+    default:
+        return nil
+    }
+}
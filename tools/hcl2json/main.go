@@ -6,7 +6,9 @@ import (
     "fmt"
     "io"
     "os"
+    "strings"
 
+    "github.com/hashicorp/hcl/v2/hclparse"
     convert "github.com/tmccombs/hcl2json/convert"
 )
 
@@ -19,11 +21,71 @@ import (
 // Usage:
 //   tfworker-hcl2json path/to/file.hcl
 //   cat file.hcl | tfworker-hcl2json --stdin
+//   tfworker-hcl2json --simplify path/to/file.hcl
+//   tfworker-hcl2json --inspect path/to/module
+//   tfworker-hcl2json --set terraform.required_providers.aws.version=\"5.0.0\" path/to/versions.tf
+//   tfworker-hcl2json --diagnostics-json path/to/broken.tf
 func main() {
     useStdin := flag.Bool("stdin", false, "read HCL from stdin")
     multi := flag.Bool("multi", false, "parse multiple files and emit {ok, errors}")
+    simplify := flag.Bool("simplify", false, "evaluate constant expressions before emitting JSON; expressions referencing unknown variables or functions are left as-is")
+    inspect := flag.Bool("inspect", false, "summarize a module directory's variables, outputs, providers, resources, and module calls")
+    dryRun := flag.Bool("dry-run", false, "with --set, print the resulting HCL to stdout instead of writing the file")
+    diagnosticsJSON := flag.Bool("diagnostics-json", false, "on error, emit HCL diagnostics as JSON to stderr instead of plain text")
+    var sets stringSliceFlag
+    flag.Var(&sets, "set", "address=value to set in-place (repeatable); value is a JSON literal, or hcl:<expr> for a raw HCL expression")
     flag.Parse()
 
+    opts := convert.Options{Simplify: *simplify}
+
+    if len(sets) > 0 {
+        if flag.NArg() != 1 {
+            fmt.Fprintln(os.Stderr, "--set requires exactly one target file")
+            os.Exit(2)
+        }
+        f, diags, err := applySets(flag.Arg(0), sets)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        if len(diags) > 0 {
+            enc := json.NewEncoder(os.Stderr)
+            enc.SetEscapeHTML(false)
+            if err := enc.Encode(diags); err != nil {
+                fmt.Fprintln(os.Stderr, err)
+            }
+            os.Exit(1)
+        }
+        if *dryRun {
+            os.Stdout.Write(f.Bytes())
+            os.Exit(0)
+        }
+        if err := os.WriteFile(flag.Arg(0), f.Bytes(), 0644); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        os.Exit(0)
+    }
+
+    if *inspect {
+        if flag.NArg() != 1 {
+            fmt.Fprintln(os.Stderr, "--inspect requires exactly one module directory")
+            os.Exit(2)
+        }
+        summary, err := inspectModule(flag.Arg(0))
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        enc := json.NewEncoder(os.Stdout)
+        enc.SetEscapeHTML(false)
+        if err := enc.Encode(summary); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        os.Exit(0)
+    }
+
     var (
         b   []byte
         err error
@@ -39,22 +101,17 @@ func main() {
             os.Exit(2)
         }
         ok := map[string]any{}
-        errs := map[string]string{}
+        errs := map[string]any{}
         for i := 0; i < flag.NArg(); i++ {
             fp := flag.Arg(i)
             b, err := os.ReadFile(fp)
             if err != nil {
-                errs[fp] = err.Error()
+                errs[fp] = []richDiagnostic{{Severity: "error", Summary: err.Error()}}
                 continue
             }
-            jb, err := convert.Bytes(b, fp, convert.Options{})
+            obj, err := convertToObject(b, fp, opts)
             if err != nil {
-                errs[fp] = err.Error()
-                continue
-            }
-            var obj map[string]any
-            if err := json.Unmarshal(jb, &obj); err != nil {
-                errs[fp] = err.Error()
+                errs[fp] = diagnosticsForError(b, fp, err)
                 continue
             }
             ok[fp] = obj
@@ -69,6 +126,7 @@ func main() {
         os.Exit(0)
     }
 
+    filename := "<stdin>"
     if *useStdin {
         b, err = io.ReadAll(os.Stdin)
         if err != nil {
@@ -80,25 +138,25 @@ func main() {
             fmt.Fprintln(os.Stderr, "expected a single HCL file path or --stdin")
             os.Exit(2)
         }
-        fp := flag.Arg(0)
-        b, err = os.ReadFile(fp)
+        filename = flag.Arg(0)
+        b, err = os.ReadFile(filename)
         if err != nil {
             fmt.Fprintln(os.Stderr, err)
             os.Exit(1)
         }
     }
 
-    // Use convert.Bytes to parse and convert to JSON bytes
-    jsonBytes, err := convert.Bytes(b, "<stdin>", convert.Options{})
+    obj, err := convertToObject(b, filename, opts)
     if err != nil {
-        fmt.Fprintln(os.Stderr, err)
-        os.Exit(1)
-    }
-
-    // Ensure we print normalized JSON (convert.Bytes already returns canonical JSON)
-    var obj map[string]any
-    if err := json.Unmarshal(jsonBytes, &obj); err != nil {
-        fmt.Fprintln(os.Stderr, err)
+        if *diagnosticsJSON {
+            enc := json.NewEncoder(os.Stderr)
+            enc.SetEscapeHTML(false)
+            if encErr := enc.Encode(diagnosticsForError(b, filename, err)); encErr != nil {
+                fmt.Fprintln(os.Stderr, encErr)
+            }
+        } else {
+            fmt.Fprintln(os.Stderr, err)
+        }
         os.Exit(1)
     }
 
@@ -109,3 +167,45 @@ func main() {
         os.Exit(1)
     }
 }
+
+// isJSONSyntax reports whether fp names a Terraform JSON-syntax file
+// (.tf.json or .tfvars.json) rather than native HCL syntax.
+func isJSONSyntax(fp string) bool {
+    return strings.HasSuffix(fp, ".tf.json") || strings.HasSuffix(fp, ".tfvars.json")
+}
+
+// convertToObject turns raw file contents into the canonical JSON structure
+// tfworker expects. Native HCL (.tf, .tfvars, .hcl) and Terraform's JSON
+// surface syntax (.tf.json, .tfvars.json) are both parsed with hclparse
+// first so a parse failure comes back as real hcl.Diagnostics with source
+// positions; native HCL that parses cleanly is then handed to convert.File
+// (convert.Bytes' own parse+convert split) to produce the JSON bytes, and
+// JSON-syntax input is decoded and run through wrapJSONBlocks so its block
+// types get the same array-wrapped shape convert.Bytes produces.
+func convertToObject(b []byte, filename string, opts convert.Options) (map[string]any, error) {
+    var obj map[string]any
+
+    if isJSONSyntax(filename) {
+        if _, diags := hclparse.NewParser().ParseJSON(b, filename); diags.HasErrors() {
+            return nil, diags
+        }
+        if err := json.Unmarshal(b, &obj); err != nil {
+            return nil, err
+        }
+        wrapJSONBlocks(obj)
+        return obj, nil
+    }
+
+    file, diags := hclparse.NewParser().ParseHCL(b, filename)
+    if diags.HasErrors() {
+        return nil, diags
+    }
+    jb, err := convert.File(file, opts)
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(jb, &obj); err != nil {
+        return nil, err
+    }
+    return obj, nil
+}
@@ -3,8 +3,11 @@ package main
 import (
     "encoding/json"
     "os"
+    "strings"
     "testing"
 
+    "github.com/hashicorp/hcl/v2"
+    "github.com/hashicorp/hcl/v2/hclwrite"
     convert "github.com/tmccombs/hcl2json/convert"
 )
 
@@ -28,6 +31,349 @@ terraform {
     }
 }
 
+func TestConvertSimplifyCollapsesLiterals(t *testing.T) {
+    fp := "../../tests/fixtures/definitions/simplify/literal.tf"
+    b, err := os.ReadFile(fp)
+    if err != nil {
+        t.Fatalf("read fixture: %v", err)
+    }
+    jb, err := convert.Bytes(b, fp, convert.Options{Simplify: true})
+    if err != nil {
+        t.Fatalf("convert fixture: %v", err)
+    }
+    var m map[string]any
+    if err := json.Unmarshal(jb, &m); err != nil {
+        t.Fatalf("unmarshal json: %v", err)
+    }
+    locals, ok := m["locals"].([]any)
+    if !ok || len(locals) == 0 {
+        t.Fatalf("expected locals block in converted output, got %v", m)
+    }
+    block := locals[0].(map[string]any)
+    if got := block["greeting"]; got != "hello-world" {
+        t.Fatalf("expected greeting to collapse to \"hello-world\", got %v", got)
+    }
+    if got := block["sum"]; got != float64(2) {
+        t.Fatalf("expected sum to collapse to 2, got %v", got)
+    }
+}
+
+func TestConvertSimplifyPreservesVariableReferences(t *testing.T) {
+    fp := "../../tests/fixtures/definitions/simplify/variable.tf"
+    b, err := os.ReadFile(fp)
+    if err != nil {
+        t.Fatalf("read fixture: %v", err)
+    }
+    jb, err := convert.Bytes(b, fp, convert.Options{Simplify: true})
+    if err != nil {
+        t.Fatalf("convert fixture: %v", err)
+    }
+    var m map[string]any
+    if err := json.Unmarshal(jb, &m); err != nil {
+        t.Fatalf("unmarshal json: %v", err)
+    }
+    locals := m["locals"].([]any)
+    block := locals[0].(map[string]any)
+    if got := block["name"]; got != "${var.foo}-bar" {
+        t.Fatalf("expected unresolved variable reference to survive, got %v", got)
+    }
+}
+
+func TestConvertToObjectJSONSyntax(t *testing.T) {
+    fp := "../../tests/fixtures/definitions/json_syntax/versions.tf.json"
+    b, err := os.ReadFile(fp)
+    if err != nil {
+        t.Fatalf("read fixture: %v", err)
+    }
+    obj, err := convertToObject(b, fp, convert.Options{})
+    if err != nil {
+        t.Fatalf("convertToObject: %v", err)
+    }
+
+    // Must match convert.Bytes' canonical shape: every block array-wrapped
+    // at its final label level, so --multi is uniform across .tf and
+    // .tf.json input.
+    terraform, ok := obj["terraform"].([]any)
+    if !ok || len(terraform) != 1 {
+        t.Fatalf("expected 'terraform' to be a one-element array, got %v", obj["terraform"])
+    }
+    block := terraform[0].(map[string]any)
+    requiredProviders, ok := block["required_providers"].([]any)
+    if !ok || len(requiredProviders) != 1 {
+        t.Fatalf("expected 'required_providers' to be a one-element array, got %v", block["required_providers"])
+    }
+    aws, ok := requiredProviders[0].(map[string]any)["aws"].(map[string]any)
+    if !ok || aws["source"] != "hashicorp/aws" {
+        t.Fatalf("expected 'aws' to stay a plain object attribute, got %v", requiredProviders[0])
+    }
+}
+
+func TestConvertToObjectMixedBatchOrderingAndIsolation(t *testing.T) {
+    // Mirrors the --multi loop: one .tf file, one well-formed .tf.json file,
+    // and one broken .tf.json file, to prove mixed HCL/JSON batches keep
+    // their per-file ordering and isolate errors from successes.
+    paths := []string{
+        "../../tests/fixtures/definitions/json_syntax/main.tf",
+        "../../tests/fixtures/definitions/json_syntax/versions.tf.json",
+        "../../tests/fixtures/definitions/json_syntax/broken.tf.json",
+    }
+    ok := map[string]any{}
+    errs := map[string]string{}
+    for _, fp := range paths {
+        b, err := os.ReadFile(fp)
+        if err != nil {
+            t.Fatalf("read fixture %s: %v", fp, err)
+        }
+        obj, err := convertToObject(b, fp, convert.Options{})
+        if err != nil {
+            errs[fp] = err.Error()
+            continue
+        }
+        ok[fp] = obj
+    }
+
+    if len(ok) != 2 {
+        t.Fatalf("expected 2 successful conversions, got %d: %v", len(ok), ok)
+    }
+    if len(errs) != 1 {
+        t.Fatalf("expected 1 conversion error, got %d: %v", len(errs), errs)
+    }
+    if _, found := errs["../../tests/fixtures/definitions/json_syntax/broken.tf.json"]; !found {
+        t.Fatalf("expected broken.tf.json to fail in isolation, got errs=%v", errs)
+    }
+    if _, found := ok["../../tests/fixtures/definitions/json_syntax/main.tf"]; !found {
+        t.Fatalf("expected main.tf to succeed alongside the JSON-syntax file")
+    }
+}
+
+func TestInspectModule(t *testing.T) {
+    summary, err := inspectModule("../../tests/fixtures/definitions/inspect_module")
+    if err != nil {
+        t.Fatalf("inspectModule: %v", err)
+    }
+
+    variables := summary["variables"].(map[string]any)
+    name := variables["name"].(map[string]any)
+    if name["type"] != "string" || name["required"] != true {
+        t.Fatalf("expected variable %q to be a required string, got %v", "name", name)
+    }
+    instanceCount := variables["instance_count"].(map[string]any)
+    if instanceCount["default"] != float64(2) || instanceCount["required"] != false {
+        t.Fatalf("expected variable %q to default to 2 and be optional, got %v", "instance_count", instanceCount)
+    }
+
+    outputs := summary["outputs"].(map[string]any)
+    if outputs["instance_id"].(map[string]any)["description"] != "ID of the created instance." {
+        t.Fatalf("expected output description to be preserved, got %v", outputs["instance_id"])
+    }
+    if outputs["secret"].(map[string]any)["sensitive"] != true {
+        t.Fatalf("expected output %q to be sensitive, got %v", "secret", outputs["secret"])
+    }
+
+    requiredProviders := summary["required_providers"].(map[string]any)
+    aws := requiredProviders["aws"].(map[string]any)
+    if aws["source"] != "hashicorp/aws" {
+        t.Fatalf("expected aws required provider source to be hashicorp/aws, got %v", aws)
+    }
+    random := requiredProviders["random"].(map[string]any)
+    if random["source"] != "hashicorp/random" {
+        t.Fatalf("expected shorthand required provider to default its source, got %v", random)
+    }
+
+    managed := summary["managed_resources"].(map[string]any)
+    if _, ok := managed["aws_instance.this"]; !ok {
+        t.Fatalf("expected managed_resources to contain aws_instance.this, got %v", managed)
+    }
+
+    data := summary["data_resources"].(map[string]any)
+    if _, ok := data["data.aws_ami.selected"]; !ok {
+        t.Fatalf("expected data_resources to contain data.aws_ami.selected, got %v", data)
+    }
+
+    calls := summary["module_calls"].(map[string]any)
+    network := calls["network"].(map[string]any)
+    if network["source"] != "./modules/network" || network["version"] != "1.0.0" {
+        t.Fatalf("expected module call network to capture source/version, got %v", network)
+    }
+
+    // zz_extra.tf.json re-declares variable "name" to exercise both
+    // --inspect's *.tf.json support and its duplicate-declaration merging:
+    // the first (variables.tf) declaration must win, and the later one
+    // must produce a diagnostic rather than silently overwriting it.
+    diags := summary["diagnostics"].([]diagnostic)
+    if len(diags) != 1 {
+        t.Fatalf("expected exactly one diagnostic for the duplicate variable, got %v", diags)
+    }
+    d := diags[0]
+    if d.Severity != "warning" || !strings.Contains(d.Summary, `Duplicate variable "name"`) {
+        t.Fatalf("expected a duplicate variable warning, got %+v", d)
+    }
+    if !strings.HasSuffix(d.File, "zz_extra.tf.json") {
+        t.Fatalf("expected the duplicate to be reported against zz_extra.tf.json, got %+v", d)
+    }
+    if name["description"] != "Name to apply to created resources." {
+        t.Fatalf("expected the first declaration's description to survive the duplicate, got %v", name)
+    }
+}
+
+func TestApplySetsMutatesAndPreservesComments(t *testing.T) {
+    fp := "../../tests/fixtures/definitions/hclwrite_set/versions.tf"
+    f, diags, err := applySets(fp, []string{
+        `terraform.required_providers.aws.version="5.0.0"`,
+        `provider.aws.region="us-west-2"`,
+        `variable.instance_count.default=3`,
+    })
+    if err != nil {
+        t.Fatalf("applySets: %v", err)
+    }
+    if len(diags) != 0 {
+        t.Fatalf("expected no diagnostics, got %v", diags)
+    }
+
+    out := string(f.Bytes())
+    for _, want := range []string{
+        `"5.0.0"`,
+        `"us-west-2"`,
+        "default = 3",
+        "# pinned until the provider 5.x migration lands",
+        `"hashicorp/aws"`,
+    } {
+        if !strings.Contains(out, want) {
+            t.Fatalf("expected output to contain %q, got:\n%s", want, out)
+        }
+    }
+
+    // Re-parse to confirm the rewrite still produces valid HCL.
+    if _, diags := hclwrite.ParseConfig(f.Bytes(), fp, hcl.InitialPos); diags.HasErrors() {
+        t.Fatalf("rewritten file failed to re-parse: %v", diags)
+    }
+}
+
+func TestApplySetsObjectKeyPreservesLayout(t *testing.T) {
+    // config's keys are out of alphabetical order and carry an inline
+    // comment; --set must patch only the targeted key's value in place,
+    // not reconstruct the whole object (which would drop the comment and
+    // re-sort the keys).
+    fp := "../../tests/fixtures/definitions/hclwrite_set/object_key_layout.tf"
+    f, diags, err := applySets(fp, []string{`provider.example.config.version="5.0.0"`})
+    if err != nil {
+        t.Fatalf("applySets: %v", err)
+    }
+    if len(diags) != 0 {
+        t.Fatalf("expected no diagnostics, got %v", diags)
+    }
+
+    out := string(f.Bytes())
+    for _, want := range []string{
+        `"5.0.0"`,
+        "# keep pinned",
+        `"example/example"`,
+    } {
+        if !strings.Contains(out, want) {
+            t.Fatalf("expected output to contain %q, got:\n%s", want, out)
+        }
+    }
+    if strings.Index(out, "version") > strings.Index(out, "source") {
+        t.Fatalf("expected version to stay before source, got:\n%s", out)
+    }
+
+    if _, diags := hclwrite.ParseConfig(f.Bytes(), fp, hcl.InitialPos); diags.HasErrors() {
+        t.Fatalf("rewritten file failed to re-parse: %v", diags)
+    }
+}
+
+func TestApplySetsReportsUnresolvableAddress(t *testing.T) {
+    fp := "../../tests/fixtures/definitions/hclwrite_set/versions.tf"
+    _, diags, err := applySets(fp, []string{`resource.aws_instance.missing.ami="ami-1"`})
+    if err != nil {
+        t.Fatalf("applySets: %v", err)
+    }
+    if len(diags) != 1 {
+        t.Fatalf("expected exactly one diagnostic, got %v", diags)
+    }
+    if !strings.Contains(diags[0].Detail, "no resource block found") {
+        t.Fatalf("expected a \"no resource block found\" diagnostic, got %v", diags[0])
+    }
+}
+
+func TestApplySetsReportsNoBlockFoundRatherThanAttributeDepth(t *testing.T) {
+    // A file with zero blocks of the addressed type must report "no <type>
+    // block found", not fall through and misreport the address as
+    // addressing too deep into an attribute value.
+    fp := "../../tests/fixtures/definitions/hclwrite_set/versions.tf"
+    _, diags, err := applySets(fp, []string{`module.network.missing.name="x"`})
+    if err != nil {
+        t.Fatalf("applySets: %v", err)
+    }
+    if len(diags) != 1 {
+        t.Fatalf("expected exactly one diagnostic, got %v", diags)
+    }
+    if !strings.Contains(diags[0].Detail, "no module block found") {
+        t.Fatalf("expected a \"no module block found\" diagnostic, got %v", diags[0])
+    }
+}
+
+func TestDiagnosticsForErrorReportsPosition(t *testing.T) {
+    fp := "../../tests/fixtures/definitions/broken/missing_brace.tf"
+    b, err := os.ReadFile(fp)
+    if err != nil {
+        t.Fatalf("read fixture: %v", err)
+    }
+    _, convErr := convertToObject(b, fp, convert.Options{})
+    if convErr == nil {
+        t.Fatalf("expected broken HCL to fail to convert")
+    }
+
+    diags := diagnosticsForError(b, fp, convErr)
+    if len(diags) == 0 {
+        t.Fatalf("expected at least one diagnostic")
+    }
+    d := diags[0]
+    if d.Severity != "error" {
+        t.Fatalf("expected error severity, got %q", d.Severity)
+    }
+    if d.Subject == nil {
+        t.Fatalf("expected a subject range with source position, got none")
+    }
+    if d.Subject.Filename != fp {
+        t.Fatalf("expected subject filename %q, got %q", fp, d.Subject.Filename)
+    }
+    if d.Subject.Start.Line == 0 {
+        t.Fatalf("expected a non-zero line number, got %+v", d.Subject.Start)
+    }
+}
+
+func TestDiagnosticsForErrorConversionFailureHasNoPosition(t *testing.T) {
+    // A conversion-time error (syntactically valid HCL that convert.File
+    // rejects, e.g. a block name used both with and without labels) has no
+    // source position available from the underlying converter, so it must
+    // still report a (positionless) diagnostic rather than silently
+    // succeeding or masking the real error.
+    fp := "../../tests/fixtures/definitions/broken/duplicate_block_labels.tf"
+    b, err := os.ReadFile(fp)
+    if err != nil {
+        t.Fatalf("read fixture: %v", err)
+    }
+    _, convErr := convertToObject(b, fp, convert.Options{})
+    if convErr == nil {
+        t.Fatalf("expected conversion to fail")
+    }
+    if _, ok := convErr.(hcl.Diagnostics); ok {
+        t.Fatalf("expected a plain conversion error, not hcl.Diagnostics")
+    }
+
+    diags := diagnosticsForError(b, fp, convErr)
+    if len(diags) != 1 {
+        t.Fatalf("expected exactly one diagnostic, got %v", diags)
+    }
+    if diags[0].Subject != nil {
+        t.Fatalf("expected no source position for a conversion-time error, got %+v", diags[0].Subject)
+    }
+    if !strings.Contains(diags[0].Summary, "invalid HCL detected") {
+        t.Fatalf("expected the underlying converter error to surface, got %q", diags[0].Summary)
+    }
+}
+
 func TestConvertFixtureVersionsTF(t *testing.T) {
     // Use a repo fixture file to ensure we handle real Terraform syntax
     fp := "../../tests/fixtures/definitions/test_a/versions.tf"
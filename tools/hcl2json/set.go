@@ -0,0 +1,345 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+
+    "github.com/hashicorp/hcl/v2"
+    "github.com/hashicorp/hcl/v2/hclsyntax"
+    "github.com/hashicorp/hcl/v2/hclwrite"
+    "github.com/zclconf/go-cty/cty"
+)
+
+// stringSliceFlag accumulates repeated occurrences of a flag, e.g.
+// `--set a=1 --set b=2`.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string { return strings.Join(*s, ",") }
+func (s *stringSliceFlag) Set(v string) error {
+    *s = append(*s, v)
+    return nil
+}
+
+// setSpec is one parsed "--set address=value" pair.
+type setSpec struct {
+    Address string
+    Raw     string
+}
+
+func parseSetSpec(s string) (setSpec, error) {
+    i := strings.Index(s, "=")
+    if i < 0 {
+        return setSpec{}, fmt.Errorf("invalid --set %q: expected address=value", s)
+    }
+    return setSpec{Address: s[:i], Raw: s[i+1:]}, nil
+}
+
+// applySets loads path with hclwrite, applies every spec in order, and
+// returns the resulting file along with structured diagnostics for any
+// address that could not be resolved. Resolved specs are always applied;
+// callers should treat a non-empty diagnostics slice as a failure.
+func applySets(path string, specs []string) (*hclwrite.File, []diagnostic, error) {
+    src, err := os.ReadFile(path)
+    if err != nil {
+        return nil, nil, err
+    }
+    f, diags := hclwrite.ParseConfig(src, path, hcl.InitialPos)
+    if diags.HasErrors() {
+        return nil, diagsToInspect(diags), nil
+    }
+
+    var problems []diagnostic
+    for _, raw := range specs {
+        spec, err := parseSetSpec(raw)
+        if err != nil {
+            problems = append(problems, diagnostic{Severity: "error", Summary: "invalid --set", Detail: err.Error()})
+            continue
+        }
+        if d := applySet(f, spec); d != nil {
+            problems = append(problems, *d)
+        }
+    }
+    return f, problems, nil
+}
+
+// applySet resolves a single dotted address against f's top-level blocks
+// and writes value into it, returning a diagnostic if the address can't be
+// resolved.
+func applySet(f *hclwrite.File, spec setSpec) *diagnostic {
+    tokens := strings.Split(spec.Address, ".")
+    body := f.Body()
+
+    i := 0
+    for i < len(tokens)-1 {
+        typeName := tokens[i]
+        blocks := blocksOfType(body, typeName)
+        if len(blocks) == 0 {
+            // The first token of a multi-token address always names a
+            // block type, never an attribute, so this is unresolvable
+            // rather than a cue to fall through to attribute handling.
+            // Deeper tokens may legitimately name an attribute instead of
+            // a nested block (e.g. required_providers.aws.version), so
+            // only i == 0 is treated as an error here.
+            if i == 0 {
+                return &diagnostic{
+                    Severity: "error",
+                    Summary:  "unresolvable address",
+                    Detail:   fmt.Sprintf("no %s block found in %s", typeName, spec.Address),
+                }
+            }
+            break
+        }
+        labelCount := len(blocks[0].Labels())
+        if i+1+labelCount >= len(tokens) {
+            break
+        }
+        labels := tokens[i+1 : i+1+labelCount]
+        blk := findBlockByLabels(blocks, labels)
+        if blk == nil {
+            return &diagnostic{
+                Severity: "error",
+                Summary:  "unresolvable address",
+                Detail:   fmt.Sprintf("no %s block with labels %v in %s", typeName, labels, spec.Address),
+            }
+        }
+        body = blk.Body()
+        i += 1 + labelCount
+    }
+
+    if i >= len(tokens) {
+        return &diagnostic{Severity: "error", Summary: "unresolvable address", Detail: fmt.Sprintf("%q does not name an attribute", spec.Address)}
+    }
+
+    attrName := tokens[i]
+    keyPath := tokens[i+1:]
+
+    if len(keyPath) == 0 {
+        return setAttribute(body, attrName, spec.Raw)
+    }
+    if len(keyPath) == 1 {
+        return setObjectKey(body, attrName, keyPath[0], spec.Raw)
+    }
+    return &diagnostic{
+        Severity: "error",
+        Summary:  "unresolvable address",
+        Detail:   fmt.Sprintf("%q addresses more than one level into an attribute value, which isn't supported", spec.Address),
+    }
+}
+
+func blocksOfType(body *hclwrite.Body, typeName string) []*hclwrite.Block {
+    var out []*hclwrite.Block
+    for _, b := range body.Blocks() {
+        if b.Type() == typeName {
+            out = append(out, b)
+        }
+    }
+    return out
+}
+
+func findBlockByLabels(blocks []*hclwrite.Block, labels []string) *hclwrite.Block {
+    for _, b := range blocks {
+        if strSliceEqual(b.Labels(), labels) {
+            return b
+        }
+    }
+    return nil
+}
+
+func strSliceEqual(a, b []string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
+
+// setAttribute sets a top-level attribute to the parsed value, either
+// replacing it with an evaluated cty.Value (JSON literal values) or raw HCL
+// tokens (values prefixed with "hcl:").
+func setAttribute(body *hclwrite.Body, name, raw string) *diagnostic {
+    if expr, ok := strings.CutPrefix(raw, "hcl:"); ok {
+        tokens, err := rawExprTokens(expr)
+        if err != nil {
+            return &diagnostic{Severity: "error", Summary: "invalid hcl expression", Detail: err.Error()}
+        }
+        body.SetAttributeRaw(name, tokens)
+        return nil
+    }
+    v, err := jsonLiteralToCty(raw)
+    if err != nil {
+        return &diagnostic{Severity: "error", Summary: "invalid value", Detail: err.Error()}
+    }
+    body.SetAttributeValue(name, v)
+    return nil
+}
+
+// setObjectKey rewrites one key of an existing object-valued attribute
+// (e.g. required_providers.aws.version). When the key already exists, only
+// its value's tokens are spliced out and replaced, so the rest of the
+// object's key order, comments, and formatting survive untouched; adding a
+// brand-new key has no existing layout to preserve, so that case falls back
+// to rebuilding the whole object value.
+func setObjectKey(body *hclwrite.Body, attrName, key, raw string) *diagnostic {
+    attr := body.GetAttribute(attrName)
+    if attr == nil {
+        return &diagnostic{Severity: "error", Summary: "unresolvable address", Detail: fmt.Sprintf("no %q attribute to set key %q on", attrName, key)}
+    }
+    if strings.HasPrefix(raw, "hcl:") {
+        return &diagnostic{Severity: "error", Summary: "unsupported value", Detail: "raw hcl: values are only supported for top-level attributes, not object keys"}
+    }
+    newVal, err := jsonLiteralToCty(raw)
+    if err != nil {
+        return &diagnostic{Severity: "error", Summary: "invalid value", Detail: err.Error()}
+    }
+
+    tokens := attr.Expr().BuildTokens(nil)
+    valueRange, current, d := objectKeyEdit(tokens, key)
+    if d != nil {
+        return d
+    }
+    if valueRange != nil {
+        spliced := make(hclwrite.Tokens, 0, len(tokens))
+        spliced = append(spliced, tokens[:valueRange.start]...)
+        spliced = append(spliced, hclwrite.TokensForValue(newVal)...)
+        spliced = append(spliced, tokens[valueRange.end:]...)
+        body.SetAttributeRaw(attrName, spliced)
+        return nil
+    }
+
+    fields := current.AsValueMap()
+    if fields == nil {
+        fields = map[string]cty.Value{}
+    }
+    fields[key] = newVal
+    body.SetAttributeValue(attrName, cty.ObjectVal(fields))
+    return nil
+}
+
+// tokenRange is a [start, end) range of indexes into an hclwrite.Tokens
+// slice, identifying the tokens that make up a single object value.
+type tokenRange struct {
+    start, end int
+}
+
+// objectKeyEdit locates key within the object-literal expression tokens
+// renders to. If key already has a value, it returns the token range of
+// that value (for an in-place splice); otherwise it returns the object's
+// current evaluated value (for the caller to merge the new key into and
+// rebuild).
+func objectKeyEdit(tokens hclwrite.Tokens, key string) (*tokenRange, cty.Value, *diagnostic) {
+    var buf bytes.Buffer
+    if _, err := tokens.WriteTo(&buf); err != nil {
+        return nil, cty.NilVal, &diagnostic{Severity: "error", Summary: "could not render expression", Detail: err.Error()}
+    }
+    expr, diags := hclsyntax.ParseExpression(buf.Bytes(), "<object-expr>", hcl.InitialPos)
+    if diags.HasErrors() {
+        return nil, cty.NilVal, &diagnostic{Severity: "error", Summary: "unresolvable address", Detail: fmt.Sprintf("current value is not a constant object: %s", diags.Error())}
+    }
+    obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+    if !ok {
+        return nil, cty.NilVal, &diagnostic{Severity: "error", Summary: "unresolvable address", Detail: "current value is not an object value"}
+    }
+
+    startAt, endAt := tokenByteOffsets(tokens)
+    for _, item := range obj.Items {
+        keyVal, kdiags := item.KeyExpr.Value(nil)
+        if kdiags.HasErrors() || keyVal.IsNull() || keyVal.Type() != cty.String || keyVal.AsString() != key {
+            continue
+        }
+        startTok, startOK := startAt[item.ValueExpr.Range().Start.Byte]
+        endTok, endOK := endAt[item.ValueExpr.Range().End.Byte]
+        if !startOK || !endOK {
+            return nil, cty.NilVal, &diagnostic{Severity: "error", Summary: "unresolvable address", Detail: fmt.Sprintf("could not locate key %q in object tokens", key)}
+        }
+        return &tokenRange{start: startTok, end: endTok + 1}, cty.NilVal, nil
+    }
+
+    current, vdiags := expr.Value(nil)
+    if vdiags.HasErrors() {
+        return nil, cty.NilVal, &diagnostic{Severity: "error", Summary: "unresolvable address", Detail: fmt.Sprintf("current value is not a constant object: %s", vdiags.Error())}
+    }
+    return nil, current, nil
+}
+
+// tokenByteOffsets maps the byte offset each token starts/ends at (as
+// produced by Tokens.WriteTo, the same rendering objectKeyEdit parses with
+// hclsyntax) to that token's index, so hclsyntax expression ranges can be
+// translated back into hclwrite token indexes.
+func tokenByteOffsets(tokens hclwrite.Tokens) (startAt, endAt map[int]int) {
+    startAt = make(map[int]int, len(tokens))
+    endAt = make(map[int]int, len(tokens))
+    offset := 0
+    for i, t := range tokens {
+        offset += t.SpacesBefore
+        startAt[offset] = i
+        offset += len(t.Bytes)
+        endAt[offset] = i
+    }
+    return startAt, endAt
+}
+
+// rawExprTokens turns a raw HCL expression string into hclwrite tokens by
+// parsing it as the right-hand side of a throwaway attribute.
+func rawExprTokens(expr string) (hclwrite.Tokens, error) {
+    synthetic := []byte("_ = " + expr + "\n")
+    f, diags := hclwrite.ParseConfig(synthetic, "<set-expr>", hcl.InitialPos)
+    if diags.HasErrors() {
+        return nil, diags
+    }
+    attr := f.Body().GetAttribute("_")
+    if attr == nil {
+        return nil, fmt.Errorf("could not parse expression %q", expr)
+    }
+    return attr.Expr().BuildTokens(nil), nil
+}
+
+// jsonLiteralToCty decodes a JSON literal (string/number/bool/array/object)
+// into the matching cty.Value.
+func jsonLiteralToCty(raw string) (cty.Value, error) {
+    var v any
+    if err := json.Unmarshal([]byte(raw), &v); err != nil {
+        return cty.NilVal, fmt.Errorf("value %q is not valid JSON: %w", raw, err)
+    }
+    return nativeToCty(v), nil
+}
+
+func nativeToCty(v any) cty.Value {
+    switch t := v.(type) {
+    case nil:
+        return cty.NullVal(cty.DynamicPseudoType)
+    case string:
+        return cty.StringVal(t)
+    case bool:
+        return cty.BoolVal(t)
+    case float64:
+        return cty.NumberFloatVal(t)
+    case json.Number:
+        f, _ := strconv.ParseFloat(t.String(), 64)
+        return cty.NumberFloatVal(f)
+    case []any:
+        vals := make([]cty.Value, len(t))
+        for i, e := range t {
+            vals[i] = nativeToCty(e)
+        }
+        if len(vals) == 0 {
+            return cty.ListValEmpty(cty.DynamicPseudoType)
+        }
+        return cty.TupleVal(vals)
+    case map[string]any:
+        fields := make(map[string]cty.Value, len(t))
+        for k, e := range t {
+            fields[k] = nativeToCty(e)
+        }
+        return cty.ObjectVal(fields)
+    default:
+        return cty.NullVal(cty.DynamicPseudoType)
+    }
+}